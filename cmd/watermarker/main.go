@@ -0,0 +1,119 @@
+// Command watermarker resizes, watermarks, and bundles a directory of scanned page
+// images into a single PDF. See pkg/watermarker for the underlying pipeline.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Cryptkeeper/watermarker/pkg/watermarker"
+)
+
+var (
+	directorySearchPath   string // path to search for input files
+	fileExtensions        string // comma-separated list of file extensions to search for
+	watermarkFilePath     string // path to watermark image
+	watermarkImageScale   int    // scale factor for watermark image
+	outputDimensionHeight int    // output page height in pixels @ 150 DPI
+	outputDimensionWidth  int    // output page width in pixels @ 150 DPI
+	outputFilePath        string // output file path for generated PDF
+	workDirectory         string // directory for writing temporary files
+	rendererBackend       string // Renderer implementation to use: "native" or "imagemagick"
+	cacheDir              string // directory for the content-addressed processed-page cache
+	cacheMaxSizeMB        int    // size cap for the processed-page cache, in megabytes
+	noCache               bool   // disables the processed-page cache entirely
+	jobs                  int    // number of concurrent page-processing workers
+	preprocFlags          stringSliceFlag // repeatable -preproc step specs, e.g. "sauvola:w=25,k=0.34"
+	pageOrder             string // page ordering strategy: "regex", "natural", or "manifest"
+	pagePattern           string // regexp with a named "number" capture group, used when -order is "regex"
+	manifestPath          string // manifest file path, used when -order is "manifest"
+	ocrEnabled            bool   // enables an OCR pass that embeds a searchable text layer in the output PDF
+	ocrLang               string // OCR language, passed through to the OCR engine
+)
+
+// stringSliceFlag implements flag.Value to support repeatable flags, collecting each
+// occurrence in the order given on the command line.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// parseArgs parses command line argument flags and configures global variables
+func parseArgs() {
+	flag.StringVar(&directorySearchPath, "dir", "", "Input directory search path for pages")
+	flag.StringVar(&fileExtensions, "ext", ".jpg,.jpeg", "A comma-separated list of supported file extensions")
+	flag.StringVar(&watermarkFilePath, "watermark", "", "Watermark file path")
+	flag.IntVar(&watermarkImageScale, "size", 4, "Watermark image size scale")
+	flag.IntVar(&outputDimensionHeight, "height", 1500, "Output page height in pixels @ 150 DPI")
+	flag.IntVar(&outputDimensionWidth, "width", 1500, "Output page width in pixels @ 150 DPI")
+	flag.StringVar(&outputFilePath, "output", "", "Output file path")
+	flag.StringVar(&workDirectory, "workdir", ".watermarker-workdir", "Work directory for temporary files")
+	flag.StringVar(&rendererBackend, "backend", "native", "Rendering backend to use: \"native\" (pure Go) or \"imagemagick\" (requires convert/magick/img2pdf on PATH)")
+	flag.StringVar(&cacheDir, "cache-dir", "_gen/pages", "Directory for the content-addressed processed-page cache")
+	flag.IntVar(&cacheMaxSizeMB, "cache-max-size", 1024, "Processed-page cache size cap, in megabytes")
+	flag.BoolVar(&noCache, "no-cache", false, "Disable the processed-page cache")
+	flag.IntVar(&jobs, "jobs", 0, "Number of concurrent page-processing workers (defaults to the number of CPUs)")
+	flag.Var(&preprocFlags, "preproc", "Scanned-document preprocessing step to apply before watermarking: deskew, wipe-margins, or sauvola[:w=N,k=F.F] (repeatable, applied in the order given)")
+	flag.StringVar(&pageOrder, "order", "regex", "Page ordering strategy: \"regex\", \"natural\", or \"manifest\"")
+	flag.StringVar(&pagePattern, "pattern", "", "Regexp with a named \"number\" capture group, used to extract page numbers when -order is \"regex\"")
+	flag.StringVar(&manifestPath, "manifest", "", "Manifest file listing page paths in order, one per line, used when -order is \"manifest\"")
+	flag.BoolVar(&ocrEnabled, "ocr", false, "Run OCR over each page and embed the recognized text as an invisible, searchable layer in the output PDF")
+	flag.StringVar(&ocrLang, "ocr-lang", "eng", "OCR language, used when -ocr is set")
+
+	flag.Parse()
+}
+
+func main() {
+	parseArgs()
+
+	if len(directorySearchPath) == 0 || len(outputFilePath) == 0 {
+		fmt.Println("Usage: watermarker -dir <directory> -output <output path> [options]")
+		flag.PrintDefaults()
+		return
+	}
+
+	cfg := watermarker.Config{
+		FileExtensions: strings.Split(fileExtensions, ","),
+		OutputWidth:    outputDimensionWidth,
+		OutputHeight:   outputDimensionHeight,
+		WatermarkPath:  watermarkFilePath,
+		WatermarkScale: watermarkImageScale,
+		Backend:        rendererBackend,
+		Jobs:           jobs,
+		CacheDir:       cacheDir,
+		CacheMaxSize:   int64(cacheMaxSizeMB) * 1024 * 1024,
+		PreprocSpecs:   preprocFlags,
+		WorkDir:        workDirectory,
+		PageOrder:      pageOrder,
+		PagePattern:    pagePattern,
+		ManifestPath:   manifestPath,
+		OCR:            ocrEnabled,
+		OCRLang:        ocrLang,
+	}
+	if noCache {
+		cfg.CacheDir = ""
+	}
+
+	out, err := os.Create(outputFilePath)
+	if err != nil {
+		fmt.Printf("error creating output file: %v\n", err)
+		return
+	}
+	defer out.Close()
+
+	fsys := os.DirFS(directorySearchPath)
+
+	if err := watermarker.Run(context.Background(), fsys, cfg, out); err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+}
@@ -0,0 +1,193 @@
+// Package cache implements a content-addressed, size-bounded cache for
+// processed page artifacts, keyed by a hash of their generation inputs. It
+// borrows the approach used by Hugo's resources/_gen/images cache: results
+// are written once under the cache directory and reused on later runs for as
+// long as the key stays the same, so re-running against an unchanged input
+// directory skips regeneration entirely.
+package cache
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache looks up and stores processed page artifacts under Dir, keyed by a
+// caller-supplied content hash. Entries are evicted oldest-accessed-first
+// once the cache exceeds MaxSizeBytes. Lookup and Store pin the entries they
+// return against eviction until the caller releases them with Release, so a
+// concurrent Store's eviction pass can never remove an artifact another
+// goroutine is still using.
+type Cache struct {
+	Dir          string
+	MaxSizeBytes int64
+
+	mu     sync.Mutex
+	pinned map[string]int
+}
+
+// New returns a Cache rooted at dir, creating the directory if it doesn't
+// already exist. MaxSizeBytes <= 0 disables eviction.
+func New(dir string, maxSizeBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	return &Cache{Dir: dir, MaxSizeBytes: maxSizeBytes, pinned: make(map[string]int)}, nil
+}
+
+// Path returns the path an entry for key would occupy, regardless of whether
+// it currently exists.
+func (c *Cache) Path(key, ext string) string {
+	return filepath.Join(c.Dir, key+ext)
+}
+
+// Lookup reports whether an entry for key exists, returning its path. A
+// successful lookup refreshes the entry's modification time so the LRU
+// eviction order reflects recent use, and pins the entry against eviction
+// until the caller calls Release on the returned path.
+func (c *Cache) Lookup(key, ext string) (string, bool) {
+	path := c.Path(key, ext)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	c.pin(path)
+
+	return path, true
+}
+
+// Release unpins a path previously returned by Lookup or Store, making it
+// eligible for eviction again. Callers must release every path once they're
+// done reading it.
+func (c *Cache) Release(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pinned[path] <= 1 {
+		delete(c.pinned, path)
+	} else {
+		c.pinned[path]--
+	}
+}
+
+func (c *Cache) pin(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pinned[path]++
+}
+
+// Store atomically writes r to the cache entry for key, returning its path.
+// The artifact is first written to a temporary file in Dir and then renamed
+// into place, so a concurrent Lookup never observes a partially written
+// entry. Store pins the new entry against eviction — the caller must call
+// Release on the returned path once done with it — then triggers eviction of
+// other, unpinned entries once the write completes.
+func (c *Cache) Store(key, ext string, r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp(c.Dir, "tmp-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", err
+	}
+
+	dst := c.Path(key, ext)
+	if err := os.Rename(tmpPath, dst); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", err
+	}
+
+	c.pin(dst)
+
+	c.evict()
+
+	return dst, nil
+}
+
+// evict removes the least-recently-accessed, unpinned entries until the
+// cache is back under MaxSizeBytes. Pinned entries still count toward the
+// size total but are never removed, so the cache may temporarily exceed
+// MaxSizeBytes while entries are in use.
+func (c *Cache) evict() {
+	if c.MaxSizeBytes <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	pinned := make(map[string]bool, len(c.pinned))
+	for path, n := range c.pinned {
+		if n > 0 {
+			pinned[path] = true
+		}
+	}
+	c.mu.Unlock()
+
+	type item struct {
+		path     string
+		size     int64
+		accessed time.Time
+	}
+
+	var items []item
+	var total int64
+
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), "tmp-") {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+
+		items = append(items, item{
+			path:     filepath.Join(c.Dir, e.Name()),
+			size:     info.Size(),
+			accessed: info.ModTime(),
+		})
+		total += info.Size()
+	}
+
+	if total <= c.MaxSizeBytes {
+		return
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].accessed.Before(items[j].accessed)
+	})
+
+	for _, it := range items {
+		if total <= c.MaxSizeBytes {
+			break
+		}
+		if pinned[it.path] {
+			continue
+		}
+		if os.Remove(it.path) == nil {
+			total -= it.size
+		}
+	}
+}
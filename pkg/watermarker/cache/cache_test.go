@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLookupPinsAgainstConcurrentEviction(t *testing.T) {
+	c, err := New(t.TempDir(), 1)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	path, err := c.Store("a", ".jpg", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	c.Release(path)
+
+	got, ok := c.Lookup("a", ".jpg")
+	if !ok {
+		t.Fatalf("Lookup: expected hit")
+	}
+
+	// simulate another goroutine's Store, whose eviction pass runs while the
+	// first lookup is still pinned and hasn't been released
+	if _, err := c.Store("b", ".jpg", strings.NewReader("world")); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	if _, err := os.Stat(got); err != nil {
+		t.Fatalf("pinned entry %s was evicted: %v", got, err)
+	}
+
+	c.Release(got)
+}
+
+func TestEvictRemovesUnpinnedEntriesOverCap(t *testing.T) {
+	c, err := New(t.TempDir(), 1)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	a, err := c.Store("a", ".jpg", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	c.Release(a)
+
+	if _, err := c.Store("b", ".jpg", strings.NewReader("world")); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	if _, err := os.Stat(a); err == nil {
+		t.Fatalf("expected unpinned entry %s to be evicted", a)
+	}
+}
+
+func TestReleaseRequiresBalancedPins(t *testing.T) {
+	c, err := New(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	path, err := c.Store("a", ".jpg", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	if _, ok := c.Lookup("a", ".jpg"); !ok {
+		t.Fatalf("Lookup: expected hit")
+	}
+
+	// two pins outstanding (Store + Lookup): one Release should leave the
+	// entry pinned, the second should fully unpin it
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.Release(path)
+	}()
+	wg.Wait()
+
+	c.mu.Lock()
+	n := c.pinned[path]
+	c.mu.Unlock()
+	if n != 1 {
+		t.Fatalf("pin count after one Release = %d, want 1", n)
+	}
+
+	c.Release(path)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n := c.pinned[path]; n != 0 {
+		t.Fatalf("pin count = %d, want 0", n)
+	}
+}
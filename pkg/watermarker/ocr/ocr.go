@@ -0,0 +1,59 @@
+// Package ocr provides an OCR engine abstraction that produces hOCR markup, the
+// format bookpipeline also builds its searchable-PDF text layer from. A Word's
+// bounding box is expressed in the pixel coordinates of the image it was
+// recognized against.
+package ocr
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Engine recognizes text in an image and returns hOCR markup describing the
+// recognized words and their bounding boxes.
+type Engine interface {
+	// Recognize runs OCR against the image at imgPath in the given language and
+	// returns hOCR output.
+	Recognize(imgPath, lang string) ([]byte, error)
+}
+
+// Word is a single recognized word and its bounding box, in image pixel coordinates.
+type Word struct {
+	Text           string
+	X0, Y0, X1, Y1 int
+}
+
+var (
+	wordSpanPattern = regexp.MustCompile(`(?s)<span class=["']ocrx_word["'][^>]*title=["']([^"']*)["'][^>]*>(.*?)</span>`)
+	bboxPattern     = regexp.MustCompile(`bbox (\d+) (\d+) (\d+) (\d+)`)
+	tagPattern      = regexp.MustCompile(`<[^>]+>`)
+)
+
+// ParseWords extracts recognized words and bounding boxes from hOCR markup,
+// skipping any span that lacks a usable bbox or recognized text.
+func ParseWords(hocr []byte) []Word {
+	var words []Word
+
+	for _, m := range wordSpanPattern.FindAllSubmatch(hocr, -1) {
+		bbox := bboxPattern.FindStringSubmatch(string(m[1]))
+		if bbox == nil {
+			continue
+		}
+
+		text := html.UnescapeString(strings.TrimSpace(tagPattern.ReplaceAllString(string(m[2]), "")))
+		if len(text) == 0 {
+			continue
+		}
+
+		x0, _ := strconv.Atoi(bbox[1])
+		y0, _ := strconv.Atoi(bbox[2])
+		x1, _ := strconv.Atoi(bbox[3])
+		y1, _ := strconv.Atoi(bbox[4])
+
+		words = append(words, Word{Text: text, X0: x0, Y0: y0, X1: x1, Y1: y1})
+	}
+
+	return words
+}
@@ -0,0 +1,35 @@
+package ocr
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Tesseract implements Engine by shelling out to the tesseract binary, requesting
+// hOCR output.
+type Tesseract struct{}
+
+func (Tesseract) Recognize(imgPath, lang string) ([]byte, error) {
+	tmp, err := os.CreateTemp("", "watermarker-hocr-*")
+	if err != nil {
+		return nil, err
+	}
+	outBase := tmp.Name()
+	_ = tmp.Close()
+	_ = os.Remove(outBase) // tesseract writes outBase.hocr itself
+
+	defer os.Remove(outBase + ".hocr")
+
+	args := []string{imgPath, outBase}
+	if len(lang) > 0 {
+		args = append(args, "-l", lang)
+	}
+	args = append(args, "hocr")
+
+	if b, err := exec.Command("tesseract", args...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("tesseract: %w: %s", err, b)
+	}
+
+	return os.ReadFile(outBase + ".hocr")
+}
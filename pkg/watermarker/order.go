@@ -0,0 +1,204 @@
+package watermarker
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// defaultPagePattern extracts the trailing run of digits from a filename, e.g.
+// "page12.jpg" -> "12". It anchors on the end of the string so the digit run
+// immediately preceding the extension wins regardless of what precedes it,
+// rather than a greedy prefix swallowing all but the last digit.
+const defaultPagePattern = `(?P<number>\d+)[^\d]*$`
+
+// PageOrderer sorts the set of ingested page files into final page order.
+type PageOrderer interface {
+	// Order returns files sorted into final page order, or an error describing
+	// every file it could not place.
+	Order(files []string) ([]string, error)
+}
+
+// newPageOrderer constructs the PageOrderer selected by cfg.PageOrder.
+func newPageOrderer(fsys fs.FS, cfg Config) (PageOrderer, error) {
+	switch cfg.pageOrder() {
+	case "regex":
+		return newRegexOrderer(cfg.pagePattern())
+	case "natural":
+		return naturalOrderer{}, nil
+	case "manifest":
+		b, err := fs.ReadFile(fsys, cfg.ManifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading manifest %q: %w", cfg.ManifestPath, err)
+		}
+
+		var lines []string
+		for _, line := range strings.Split(string(b), "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) > 0 {
+				lines = append(lines, line)
+			}
+		}
+
+		return manifestOrderer{lines: lines}, nil
+	default:
+		return nil, fmt.Errorf("unknown page order strategy: %q", cfg.PageOrder)
+	}
+}
+
+// regexOrderer extracts a page number from each filename using a user-supplied
+// regexp with a named "number" capture group, and sorts numerically.
+type regexOrderer struct {
+	pattern *regexp.Regexp
+}
+
+func newRegexOrderer(pattern string) (*regexOrderer, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page pattern: %w", err)
+	}
+
+	if re.SubexpIndex("number") < 0 {
+		return nil, fmt.Errorf("page pattern must contain a named \"number\" capture group")
+	}
+
+	return &regexOrderer{pattern: re}, nil
+}
+
+func (o *regexOrderer) Order(files []string) ([]string, error) {
+	type numbered struct {
+		path   string
+		number int
+	}
+
+	idx := o.pattern.SubexpIndex("number")
+
+	var ordered []numbered
+	var unmatched []string
+
+	for _, f := range files {
+		matches := o.pattern.FindStringSubmatch(filepath.Base(f))
+		if matches == nil || len(matches[idx]) == 0 {
+			unmatched = append(unmatched, f)
+			continue
+		}
+
+		n, err := strconv.Atoi(matches[idx])
+		if err != nil {
+			unmatched = append(unmatched, f)
+			continue
+		}
+
+		ordered = append(ordered, numbered{path: f, number: n})
+	}
+
+	if len(unmatched) > 0 {
+		return nil, fmt.Errorf("no page number found in %d file(s): %s", len(unmatched), strings.Join(unmatched, ", "))
+	}
+
+	slices.SortFunc(ordered, func(a, b numbered) int {
+		return a.number - b.number
+	})
+
+	out := make([]string, len(ordered))
+	for i, n := range ordered {
+		out[i] = n.path
+	}
+
+	return out, nil
+}
+
+// naturalOrderer sorts the full basename of each file, splitting it into
+// alphabetic and numeric runs and comparing numeric runs by value rather than
+// lexicographically. This avoids the regexOrderer's hard failure on filenames
+// without digits, and sorts inconsistently-padded numbers (page2 vs page10)
+// correctly.
+type naturalOrderer struct{}
+
+func (naturalOrderer) Order(files []string) ([]string, error) {
+	sorted := append([]string(nil), files...)
+
+	slices.SortFunc(sorted, func(a, b string) int {
+		return compareNatural(filepath.Base(a), filepath.Base(b))
+	})
+
+	return sorted, nil
+}
+
+var naturalRunPattern = regexp.MustCompile(`\d+|\D+`)
+
+func compareNatural(a, b string) int {
+	as := naturalRunPattern.FindAllString(a, -1)
+	bs := naturalRunPattern.FindAllString(b, -1)
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+
+		if aErr == nil && bErr == nil {
+			if an != bn {
+				return an - bn
+			}
+			continue
+		}
+
+		if as[i] != bs[i] {
+			if as[i] < bs[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return len(as) - len(bs)
+}
+
+// manifestOrderer returns files in the exact order listed in a manifest file,
+// one path per line, erroring if the manifest references a file that wasn't
+// found in the search directory, or if the search directory turned up a file
+// the manifest doesn't mention — the manifest is the authoritative page list,
+// so a book silently missing a page is worse than a loud failure.
+type manifestOrderer struct {
+	lines []string
+}
+
+func (o manifestOrderer) Order(files []string) ([]string, error) {
+	found := make(map[string]bool, len(files))
+	for _, f := range files {
+		found[f] = true
+	}
+
+	listed := make(map[string]bool, len(o.lines))
+	for _, line := range o.lines {
+		listed[line] = true
+	}
+
+	var missing []string
+	for _, line := range o.lines {
+		if !found[line] {
+			missing = append(missing, line)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("manifest references %d file(s) not found in the search directory: %s", len(missing), strings.Join(missing, ", "))
+	}
+
+	var omitted []string
+	for _, f := range files {
+		if !listed[f] {
+			omitted = append(omitted, f)
+		}
+	}
+
+	if len(omitted) > 0 {
+		return nil, fmt.Errorf("%d file(s) found in the search directory are missing from the manifest: %s", len(omitted), strings.Join(omitted, ", "))
+	}
+
+	return o.lines, nil
+}
@@ -0,0 +1,91 @@
+package watermarker
+
+import "testing"
+
+func TestRegexOrdererDefaultPattern(t *testing.T) {
+	o, err := newRegexOrderer(defaultPagePattern)
+	if err != nil {
+		t.Fatalf("newRegexOrderer: %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"page1.jpg", "1"},
+		{"page2.jpg", "2"},
+		{"page10.jpg", "10"},
+		{"page100.jpg", "100"},
+		{"IMG_2023_page07.jpg", "07"},
+	}
+
+	for _, tt := range tests {
+		matches := o.pattern.FindStringSubmatch(tt.file)
+		if matches == nil {
+			t.Errorf("%s: no match", tt.file)
+			continue
+		}
+		got := matches[o.pattern.SubexpIndex("number")]
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestRegexOrdererDefaultPatternSortsDoubleDigitsCorrectly(t *testing.T) {
+	o, err := newRegexOrderer(defaultPagePattern)
+	if err != nil {
+		t.Fatalf("newRegexOrderer: %v", err)
+	}
+
+	in := []string{"page10.jpg", "page1.jpg", "page11.jpg", "page2.jpg", "page3.jpg"}
+	want := []string{"page1.jpg", "page2.jpg", "page3.jpg", "page10.jpg", "page11.jpg"}
+
+	got, err := o.Order(in)
+	if err != nil {
+		t.Fatalf("Order: %v", err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Order() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Order() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestManifestOrdererDetectsOmittedFile(t *testing.T) {
+	o := manifestOrderer{lines: []string{"page1.jpg", "page2.jpg"}}
+
+	_, err := o.Order([]string{"page1.jpg", "page2.jpg", "page3.jpg"})
+	if err == nil {
+		t.Fatalf("Order: expected error for file omitted from manifest, got nil")
+	}
+}
+
+func TestManifestOrdererDetectsMissingFile(t *testing.T) {
+	o := manifestOrderer{lines: []string{"page1.jpg", "page2.jpg"}}
+
+	_, err := o.Order([]string{"page1.jpg"})
+	if err == nil {
+		t.Fatalf("Order: expected error for manifest entry missing from the search directory, got nil")
+	}
+}
+
+func TestManifestOrdererOrdersExactly(t *testing.T) {
+	o := manifestOrderer{lines: []string{"page2.jpg", "page1.jpg"}}
+
+	got, err := o.Order([]string{"page1.jpg", "page2.jpg"})
+	if err != nil {
+		t.Fatalf("Order: %v", err)
+	}
+
+	want := []string{"page2.jpg", "page1.jpg"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Order() = %v, want %v", got, want)
+		}
+	}
+}
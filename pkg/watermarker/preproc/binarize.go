@@ -0,0 +1,92 @@
+package preproc
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Binarize converts a page to black and white using Sauvola adaptive
+// thresholding: for each pixel, the local mean μ and standard deviation σ are
+// computed over a W×W window (via an integral image, so every pixel is O(1)
+// regardless of window size) and the pixel is thresholded at
+// μ·(1 + K·(σ/128 − 1)).
+type Binarize struct {
+	W int     // window size; defaults to 19 when <= 0
+	K float64 // sensitivity; defaults to 0.3 when 0
+}
+
+func (b Binarize) window() int {
+	if b.W <= 0 {
+		return 19
+	}
+	return b.W
+}
+
+func (b Binarize) k() float64 {
+	if b.K == 0 {
+		return 0.3
+	}
+	return b.K
+}
+
+func (b Binarize) Process(img image.Image) (image.Image, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	gray := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			gray.Set(x, y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	// Integral images of pixel values and squared pixel values give O(1)
+	// windowed mean/variance lookups regardless of window size.
+	sum := make([][]int64, height+1)
+	sumSq := make([][]int64, height+1)
+	for i := range sum {
+		sum[i] = make([]int64, width+1)
+		sumSq[i] = make([]int64, width+1)
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := int64(gray.GrayAt(x, y).Y)
+			sum[y+1][x+1] = v + sum[y][x+1] + sum[y+1][x] - sum[y][x]
+			sumSq[y+1][x+1] = v*v + sumSq[y][x+1] + sumSq[y+1][x] - sumSq[y][x]
+		}
+	}
+
+	half := b.window() / 2
+	k := b.k()
+
+	out := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := maxInt(0, y-half), minInt(height, y+half+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := maxInt(0, x-half), minInt(width, x+half+1)
+
+			n := int64(y1-y0) * int64(x1-x0)
+			s := sum[y1][x1] - sum[y0][x1] - sum[y1][x0] + sum[y0][x0]
+			sq := sumSq[y1][x1] - sumSq[y0][x1] - sumSq[y1][x0] + sumSq[y0][x0]
+
+			mean := float64(s) / float64(n)
+			variance := float64(sq)/float64(n) - mean*mean
+			if variance < 0 {
+				variance = 0
+			}
+			stddev := math.Sqrt(variance)
+
+			threshold := mean * (1 + k*(stddev/128-1))
+
+			value := color.Gray{Y: 0}
+			if float64(gray.GrayAt(x, y).Y) >= threshold {
+				value = color.Gray{Y: 255}
+			}
+			out.SetGray(x, y, value)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,89 @@
+package preproc
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/disintegration/imaging"
+)
+
+// Deskew estimates a scanned page's rotation and corrects it. The angle is
+// found by searching a range of candidate angles and picking the one that
+// maximizes the variance of horizontal row-darkness projections: text lines
+// produce alternating dark/light rows only when the page is level, so the
+// correctly-rotated angle stands out as the sharpest projection.
+type Deskew struct {
+	MaxAngle float64 // search range in degrees on either side of 0; defaults to 5
+	Step     float64 // search step in degrees; defaults to 0.25
+}
+
+func (d Deskew) maxAngle() float64 {
+	if d.MaxAngle <= 0 {
+		return 5
+	}
+	return d.MaxAngle
+}
+
+func (d Deskew) step() float64 {
+	if d.Step <= 0 {
+		return 0.25
+	}
+	return d.Step
+}
+
+func (d Deskew) Process(img image.Image) (image.Image, error) {
+	angle := d.estimateAngle(img)
+	if angle == 0 {
+		return img, nil
+	}
+
+	return imaging.Rotate(img, -angle, color.White), nil
+}
+
+// estimateAngle searches [-maxAngle, maxAngle] for the rotation that maximizes
+// row-darkness variance, working against a downsampled copy of img for speed.
+func (d Deskew) estimateAngle(img image.Image) float64 {
+	small := imaging.Resize(img, 400, 0, imaging.Box)
+
+	var best, bestVariance float64
+	bestVariance = -1
+
+	for angle := -d.maxAngle(); angle <= d.maxAngle(); angle += d.step() {
+		rotated := imaging.Rotate(small, -angle, color.White)
+		if v := rowDarknessVariance(rotated); v > bestVariance {
+			best, bestVariance = angle, v
+		}
+	}
+
+	return best
+}
+
+// rowDarknessVariance sums per-row darkness and returns the variance across rows.
+func rowDarknessVariance(img image.Image) float64 {
+	bounds := img.Bounds()
+	rows := make([]float64, bounds.Dy())
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		var total float64
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			lum := (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 0xffff
+			total += 1 - lum
+		}
+		rows[y-bounds.Min.Y] = total
+	}
+
+	var mean float64
+	for _, v := range rows {
+		mean += v
+	}
+	mean /= float64(len(rows))
+
+	var variance float64
+	for _, v := range rows {
+		d := v - mean
+		variance += d * d
+	}
+
+	return variance / float64(len(rows))
+}
@@ -0,0 +1,27 @@
+// Package preproc implements optional scanned-document cleanup steps that run
+// after a page is resized and before it is watermarked: deskewing, Sauvola
+// binarization, and margin wiping. These target the most common artifacts in
+// raw phone-camera book captures, producing cleaner, smaller output pages.
+package preproc
+
+import "image"
+
+// Preprocessor applies a scanned-document cleanup transformation to a page
+// image, returning the transformed result.
+type Preprocessor interface {
+	Process(img image.Image) (image.Image, error)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,132 @@
+package preproc
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// WipeMargins finds the largest connected light region in the page — the
+// paper the scanned content sits on — and blanks every pixel outside its
+// bounding box plus Padding. This removes the dark margins and scanner-bed
+// artifacts that phone and flatbed captures tend to leave around the page: a
+// margin wraps all four edges of the page, so it is the light interior, not
+// the margin itself, that forms one large connected region.
+type WipeMargins struct {
+	Threshold uint8 // luminance (0-255) below which a pixel is "dark"; defaults to 128 when 0
+	Padding   int   // pixels kept around the detected content; defaults to 10 when <= 0
+}
+
+func (w WipeMargins) threshold() uint8 {
+	if w.Threshold == 0 {
+		return 128
+	}
+	return w.Threshold
+}
+
+func (w WipeMargins) padding() int {
+	if w.Padding <= 0 {
+		return 10
+	}
+	return w.Padding
+}
+
+func (w WipeMargins) Process(img image.Image) (image.Image, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	mask := make([][]bool, height)
+	for y := 0; y < height; y++ {
+		mask[y] = make([]bool, width)
+		for x := 0; x < width; x++ {
+			mask[y][x] = !isDark(img.At(bounds.Min.X+x, bounds.Min.Y+y), w.threshold())
+		}
+	}
+
+	region := largestConnectedRegion(mask)
+	if region == nil {
+		return img, nil
+	}
+
+	pad := w.padding()
+	minX := maxInt(0, region.minX-pad)
+	minY := maxInt(0, region.minY-pad)
+	maxX := minInt(width-1, region.maxX+pad)
+	maxY := minInt(height-1, region.maxY+pad)
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(out, out.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			out.Set(x, y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	return out, nil
+}
+
+type contentRegion struct {
+	minX, minY, maxX, maxY int
+}
+
+// largestConnectedRegion finds the largest 4-connected component of set
+// pixels in mask via flood fill, returning its bounding box.
+func largestConnectedRegion(mask [][]bool) *contentRegion {
+	height := len(mask)
+	if height == 0 {
+		return nil
+	}
+	width := len(mask[0])
+
+	visited := make([][]bool, height)
+	for y := range visited {
+		visited[y] = make([]bool, width)
+	}
+
+	var best *contentRegion
+	bestSize := 0
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !mask[y][x] || visited[y][x] {
+				continue
+			}
+
+			region := &contentRegion{minX: x, minY: y, maxX: x, maxY: y}
+			size := 0
+
+			queue := [][2]int{{x, y}}
+			visited[y][x] = true
+
+			for len(queue) > 0 {
+				p := queue[len(queue)-1]
+				queue = queue[:len(queue)-1]
+				size++
+
+				region.minX, region.maxX = minInt(region.minX, p[0]), maxInt(region.maxX, p[0])
+				region.minY, region.maxY = minInt(region.minY, p[1]), maxInt(region.maxY, p[1])
+
+				for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+					nx, ny := p[0]+d[0], p[1]+d[1]
+					if nx < 0 || nx >= width || ny < 0 || ny >= height || visited[ny][nx] || !mask[ny][nx] {
+						continue
+					}
+					visited[ny][nx] = true
+					queue = append(queue, [2]int{nx, ny})
+				}
+			}
+
+			if size > bestSize {
+				bestSize, best = size, region
+			}
+		}
+	}
+
+	return best
+}
+
+func isDark(c color.Color, threshold uint8) bool {
+	r, g, b, _ := c.RGBA()
+	gray := uint8(0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8))
+	return gray < threshold
+}
@@ -0,0 +1,61 @@
+package preproc
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// bordered returns a white page of size n×n with a solid black border
+// borderWidth pixels wide, plus a small isolated black speck near the
+// center to stand in for a line of text.
+func bordered(n, borderWidth int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, n, n))
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			if x < borderWidth || y < borderWidth || x >= n-borderWidth || y >= n-borderWidth {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	mid := n / 2
+	img.Set(mid, mid, color.Black)
+
+	return img
+}
+
+func TestWipeMarginsRemovesContiguousBorder(t *testing.T) {
+	img := bordered(100, 20)
+
+	out, err := WipeMargins{}.Process(img)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	if isDark(out.At(5, 5), 128) {
+		t.Fatalf("border pixel at (5,5) still dark after WipeMargins")
+	}
+	if isDark(out.At(95, 95), 128) {
+		t.Fatalf("border pixel at (95,95) still dark after WipeMargins")
+	}
+}
+
+func TestWipeMarginsKeepsIsolatedDarkContent(t *testing.T) {
+	img := bordered(100, 20)
+
+	out, err := WipeMargins{}.Process(img)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+
+	if !isDark(out.At(50, 50), 128) {
+		t.Fatalf("content speck at (50,50) was blanked, expected it kept")
+	}
+}
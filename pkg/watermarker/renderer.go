@@ -0,0 +1,66 @@
+package watermarker
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Renderer performs the per-page image processing (auto-orient, resize, watermark
+// compositing) and the final PDF assembly. It exists so the tool is not hard-wired
+// to a particular image/PDF toolchain: the "imagemagick" implementation shells out
+// to ImageMagick and img2pdf as before, while "native" does the same work with
+// pure-Go libraries and requires nothing to be installed on the host.
+type Renderer interface {
+	// Convert reads src, auto-orients and resizes it to width x height, and writes
+	// the result to dstPath.
+	Convert(src fs.File, dstPath string, width, height int) error
+
+	// Watermark composites watermark onto dstPath in place, scaling it to fit
+	// within 1/scale of dstPath's width and height, keeping its aspect ratio.
+	Watermark(dstPath string, watermark fs.File, scale int) error
+
+	// Bundle assembles the given image files, in order, into a single PDF written to
+	// w. hocrPaths is parallel to files; a non-empty entry embeds an invisible,
+	// searchable text layer built from that hOCR document over the corresponding
+	// page. Implementations that can't support a text layer ignore hocrPaths.
+	Bundle(files []string, hocrPaths []string, w io.Writer) error
+}
+
+// copyToTempFile copies src to a new temporary file sharing src's extension and
+// returns its path. It exists so backends that need a real filesystem path (the
+// imagemagick backend shells out to binaries that do) can still accept an fs.File,
+// which may come from any fs.FS, not just the local disk.
+func copyToTempFile(src fs.File) (string, error) {
+	info, err := src.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp("", "watermarker-src-*"+filepath.Ext(info.Name()))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, src); err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// newRenderer constructs the Renderer selected by Config.Backend.
+func newRenderer(backend string) (Renderer, error) {
+	switch backend {
+	case "native":
+		return &nativeRenderer{}, nil
+	case "imagemagick":
+		return &imagemagickRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown backend: %s", backend)
+	}
+}
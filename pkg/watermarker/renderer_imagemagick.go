@@ -0,0 +1,106 @@
+package watermarker
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+)
+
+// imagemagickRenderer implements Renderer by shelling out to the ImageMagick
+// "convert"/"magick" binaries and img2pdf. It is kept around for parity with
+// existing pipelines that may depend on ImageMagick-specific behaviour, but
+// requires all three binaries to be installed on the host.
+type imagemagickRenderer struct{}
+
+func (r *imagemagickRenderer) Convert(src fs.File, dstPath string, width, height int) error {
+	defer src.Close()
+
+	srcPath, err := copyToTempFile(src)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(srcPath)
+
+	dims := fmt.Sprintf("%dx%d", width, height)
+	b, err := exec.Command("convert", srcPath, "-auto-orient", "-resize", dims, "-density", "150", "-strip", dstPath).CombinedOutput()
+
+	printCommandOutput(b, err)
+
+	return err
+}
+
+func (r *imagemagickRenderer) Watermark(dstPath string, watermark fs.File, scale int) error {
+	defer watermark.Close()
+
+	watermarkPath, err := copyToTempFile(watermark)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(watermarkPath)
+
+	b, err := exec.Command("magick",
+		dstPath,
+		"-colorspace", "sRGB",
+		"-set", "option:WMSIZE", fmt.Sprintf("%%[fx:w/%d]x%%[fx:h/%d]", scale, scale),
+		"(", // subcommand start
+		watermarkPath,
+		"-resize",
+		"%[WMSIZE]",
+		")", // subcommand end
+		"-geometry", "+25+25",
+		"-composite",
+		dstPath,
+	).CombinedOutput()
+
+	printCommandOutput(b, err)
+
+	return err
+}
+
+func (r *imagemagickRenderer) Bundle(files []string, hocrPaths []string, w io.Writer) error {
+	for _, h := range hocrPaths {
+		if len(h) > 0 {
+			fmt.Println("warning: -backend imagemagick cannot embed an OCR text layer; ignoring recognized text")
+			break
+		}
+	}
+
+	out, err := os.CreateTemp("", "watermarker-out-*.pdf")
+	if err != nil {
+		return err
+	}
+	outPath := out.Name()
+	_ = out.Close()
+	defer os.Remove(outPath)
+
+	// NOTE: this operates by passing all files to img2pdf at once, which WILL be inefficient for large numbers of
+	// files and may potentially be impacted by platform limits on command line length. Use -backend native to avoid
+	// this entirely.
+	b, err := exec.Command("img2pdf", append([]string{
+		"--output", outPath,
+	}, files...)...).CombinedOutput()
+
+	printCommandOutput(b, err)
+
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// printCommandOutput prints the output of an exec.Command call to stdout if an error occurred
+func printCommandOutput(b []byte, err error) {
+	if err != nil {
+		fmt.Println(string(b))
+	}
+}
@@ -0,0 +1,144 @@
+package watermarker
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/Cryptkeeper/watermarker/pkg/watermarker/ocr"
+	"github.com/disintegration/imaging"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// nativeRenderer implements Renderer entirely in Go, using imaging for
+// decode/resize/auto-orient/composite and gofpdf to stream pages directly
+// into the output PDF. It requires no external binaries and, unlike
+// imagemagickRenderer, reads its fs.File inputs directly instead of first
+// copying them to a real filesystem path.
+type nativeRenderer struct{}
+
+func (r *nativeRenderer) Convert(src fs.File, dstPath string, width, height int) error {
+	defer src.Close()
+
+	// imaging.Decode applies EXIF auto-orientation only when the AutoOrientation
+	// option enables it.
+	img, err := imaging.Decode(src, imaging.AutoOrientation(true))
+	if err != nil {
+		return err
+	}
+
+	resized := imaging.Fit(img, width, height, imaging.Lanczos)
+
+	return imaging.Save(resized, dstPath)
+}
+
+func (r *nativeRenderer) Watermark(dstPath string, watermark fs.File, scale int) error {
+	defer watermark.Close()
+
+	if scale <= 0 {
+		return fmt.Errorf("invalid watermark scale: %d", scale)
+	}
+
+	dst, err := imaging.Open(dstPath)
+	if err != nil {
+		return err
+	}
+
+	wm, err := imaging.Decode(watermark)
+	if err != nil {
+		return err
+	}
+
+	bounds := dst.Bounds()
+	wm = imaging.Fit(wm, bounds.Dx()/scale, bounds.Dy()/scale, imaging.Lanczos)
+
+	composited := imaging.Overlay(dst, wm, image.Pt(25, 25), 1.0)
+
+	return imaging.Save(composited, dstPath)
+}
+
+// pxToPt converts a pixel length at 150 DPI to PDF points (72 per inch).
+func pxToPt(px int) float64 {
+	return float64(px) * 72 / 150
+}
+
+// decodeImageConfig reads just the header of the image at path, returning its
+// pixel dimensions and the gofpdf image type string ("jpg" or "png").
+func decodeImageConfig(path string) (image.Config, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Config{}, "", err
+	}
+	defer f.Close()
+
+	cfg, format, err := image.DecodeConfig(f)
+	if err != nil {
+		return image.Config{}, "", err
+	}
+
+	if format == "jpeg" {
+		format = "jpg"
+	}
+
+	return cfg, format, nil
+}
+
+func (r *nativeRenderer) Bundle(files []string, hocrPaths []string, w io.Writer) error {
+	pdf := gofpdf.New("P", "pt", "", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetFont("Helvetica", "", 10)
+
+	for i, f := range files {
+		cfg, imgType, err := decodeImageConfig(f)
+		if err != nil {
+			return fmt.Errorf("reading image dimensions for %s: %w", f, err)
+		}
+
+		pw, ph := pxToPt(cfg.Width), pxToPt(cfg.Height)
+
+		pdf.AddPageFormat("P", gofpdf.SizeType{Wd: pw, Ht: ph})
+		pdf.RegisterImageOptions(f, gofpdf.ImageOptions{ImageType: imgType})
+		pdf.ImageOptions(f, 0, 0, pw, ph, false, gofpdf.ImageOptions{ImageType: imgType}, 0, "")
+
+		if i < len(hocrPaths) && len(hocrPaths[i]) > 0 {
+			if err := writeTextLayer(pdf, hocrPaths[i]); err != nil {
+				return fmt.Errorf("writing text layer for %s: %w", f, err)
+			}
+		}
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+
+	return pdf.Output(w)
+}
+
+// writeTextLayer overlays an invisible, searchable text layer onto the current PDF
+// page from hocrPath, positioning each recognized word at its hOCR bounding box,
+// translated from image pixel coordinates into PDF points. Text is drawn at zero
+// alpha rather than omitted so it stays selectable and searchable in PDF readers.
+func writeTextLayer(pdf *gofpdf.Fpdf, hocrPath string) error {
+	b, err := os.ReadFile(hocrPath)
+	if err != nil {
+		return err
+	}
+
+	pdf.SetAlpha(0, "Normal")
+	defer pdf.SetAlpha(1, "Normal")
+
+	for _, word := range ocr.ParseWords(b) {
+		height := pxToPt(word.Y1 - word.Y0)
+
+		pdf.SetFontSize(height * 0.75)
+		pdf.SetXY(pxToPt(word.X0), pxToPt(word.Y0))
+		pdf.CellFormat(pxToPt(word.X1-word.X0), height, word.Text, "", 0, "", false, 0, "")
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package watermarker
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// memFile adapts an in-memory reader to fs.File, the type Renderer.Watermark
+// takes its watermark as, without touching disk.
+type memFile struct {
+	*bytes.Reader
+}
+
+func (memFile) Close() error               { return nil }
+func (memFile) Stat() (fs.FileInfo, error) { return memFileInfo{}, nil }
+
+type memFileInfo struct{}
+
+func (memFileInfo) Name() string       { return "watermark.jpg" }
+func (memFileInfo) Size() int64        { return 0 }
+func (memFileInfo) Mode() fs.FileMode  { return 0 }
+func (memFileInfo) ModTime() time.Time { return time.Time{} }
+func (memFileInfo) IsDir() bool        { return false }
+func (memFileInfo) Sys() any           { return nil }
+
+func jpegBytes(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestNativeRendererWatermarkRejectsNonPositiveScale(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "page.jpg")
+	if err := os.WriteFile(dst, jpegBytes(t, 100, 100), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	r := &nativeRenderer{}
+
+	for _, scale := range []int{0, -1} {
+		wm := memFile{bytes.NewReader(jpegBytes(t, 10, 10))}
+		if err := r.Watermark(dst, wm, scale); err == nil {
+			t.Fatalf("Watermark(scale=%d): expected error, got nil", scale)
+		}
+	}
+}
@@ -0,0 +1,494 @@
+// Package watermarker implements the page ingest, processing, and PDF
+// bundling pipeline behind the watermarker CLI. It is factored out of
+// cmd/watermarker so the pipeline can be embedded in other front-ends (HTTP
+// services, tests, GUI wrappers) without going through flag-parsed globals.
+package watermarker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/Cryptkeeper/watermarker/pkg/watermarker/cache"
+	"github.com/Cryptkeeper/watermarker/pkg/watermarker/ocr"
+	"github.com/Cryptkeeper/watermarker/pkg/watermarker/preproc"
+	"github.com/disintegration/imaging"
+	"golang.org/x/exp/slices"
+	"golang.org/x/sync/errgroup"
+)
+
+// Config configures a single watermarking run.
+type Config struct {
+	FileExtensions []string // file extensions to include when walking the input fs.FS; defaults to .jpg,.jpeg
+
+	OutputWidth  int // output page width in pixels @ 150 DPI
+	OutputHeight int // output page height in pixels @ 150 DPI
+
+	WatermarkPath  string // OS filesystem path to the watermark image; empty disables watermarking
+	WatermarkScale int    // watermark image size scale; must be positive when WatermarkPath is set
+
+	Backend string // rendering backend: "native" or "imagemagick"; defaults to "native"
+	Jobs    int    // number of concurrent page-processing workers; defaults to runtime.NumCPU()
+
+	CacheDir     string // directory for the content-addressed processed-page cache; empty disables caching
+	CacheMaxSize int64  // cache size cap in bytes; <= 0 disables eviction
+
+	PreprocSpecs []string // repeatable preprocessing step specs (see parsePreprocessors), applied in the order given
+
+	WorkDir string // directory for writing temporary files
+
+	PageOrder    string // page ordering strategy: "regex" (default), "natural", or "manifest"
+	PagePattern  string // regexp with a named "number" capture group, used when PageOrder is "regex"
+	ManifestPath string // path, within the input fs.FS, to the manifest file, used when PageOrder is "manifest"
+
+	OCR     bool   // enables an OCR pass that embeds a searchable text layer in the output PDF
+	OCRLang string // OCR language, passed through to the OCR engine; defaults to "eng"
+}
+
+// Run ingests pages from fsys, processes them according to cfg, and writes the
+// resulting PDF to out.
+func Run(ctx context.Context, fsys fs.FS, cfg Config, out io.Writer) error {
+	renderer, err := newRenderer(cfg.backend())
+	if err != nil {
+		return fmt.Errorf("selecting backend: %w", err)
+	}
+
+	var pageCache *cache.Cache
+	if len(cfg.CacheDir) > 0 {
+		if pageCache, err = cache.New(cfg.CacheDir, cfg.CacheMaxSize); err != nil {
+			return fmt.Errorf("initializing cache: %w", err)
+		}
+	}
+
+	preprocSteps, err := parsePreprocessors(cfg.PreprocSpecs)
+	if err != nil {
+		return fmt.Errorf("parsing preprocessing steps: %w", err)
+	}
+
+	orderer, err := newPageOrderer(fsys, cfg)
+	if err != nil {
+		return fmt.Errorf("selecting page order: %w", err)
+	}
+
+	pages, err := ingestPages(fsys, cfg.fileExtensions(), orderer)
+	if err != nil {
+		return fmt.Errorf("ingesting pages: %w", err)
+	}
+
+	var ocrEngine ocr.Engine
+	if cfg.OCR {
+		ocrEngine = ocr.Tesseract{}
+	}
+
+	preprocSpec := strings.Join(cfg.PreprocSpecs, "|")
+	if err := genProcessedPages(ctx, fsys, pages, renderer, cfg, pageCache, preprocSteps, preprocSpec, ocrEngine); err != nil {
+		return fmt.Errorf("processing pages: %w", err)
+	}
+
+	// keep every cache-owned artifact pinned against eviction until we're done
+	// reading it below, so a page generated early in the batch can't be evicted
+	// by a later page's Store before Bundle gets to read it
+	if pageCache != nil {
+		defer func() {
+			for _, p := range pages {
+				if p.cached {
+					pageCache.Release(p.tmpPath)
+				}
+			}
+		}()
+	}
+
+	files := make([]string, len(pages))
+	hocrPaths := make([]string, len(pages))
+	for i, p := range pages {
+		files[i] = p.tmpPath
+		hocrPaths[i] = p.hocrPath
+	}
+
+	if err := renderer.Bundle(files, hocrPaths, out); err != nil {
+		return fmt.Errorf("bundling pages: %w", err)
+	}
+
+	// attempt to clear leftover temp files; cached artifacts live under CacheDir and are
+	// intentionally left in place for reuse by future runs
+	for _, p := range pages {
+		if pageCache == nil {
+			_ = os.Remove(p.tmpPath)
+		}
+		if len(p.hocrPath) > 0 {
+			_ = os.Remove(p.hocrPath)
+		}
+	}
+
+	return nil
+}
+
+// parsePreprocessors converts Config.PreprocSpecs into configured
+// preproc.Preprocessor implementations, applied in the order given. Each spec is
+// either a bare step name ("deskew", "wipe-margins") or a name followed by
+// comma-separated key=value parameters ("sauvola:w=25,k=0.34").
+func parsePreprocessors(specs []string) ([]preproc.Preprocessor, error) {
+	var steps []preproc.Preprocessor
+
+	for _, spec := range specs {
+		name, params, _ := strings.Cut(spec, ":")
+
+		switch name {
+		case "deskew":
+			steps = append(steps, preproc.Deskew{})
+		case "wipe-margins":
+			steps = append(steps, preproc.WipeMargins{})
+		case "sauvola":
+			b := preproc.Binarize{}
+			for _, kv := range strings.Split(params, ",") {
+				if len(kv) == 0 {
+					continue
+				}
+
+				key, value, ok := strings.Cut(kv, "=")
+				if !ok {
+					return nil, fmt.Errorf("invalid sauvola parameter: %q", kv)
+				}
+
+				switch key {
+				case "w":
+					n, err := strconv.Atoi(value)
+					if err != nil {
+						return nil, fmt.Errorf("invalid sauvola window %q: %w", value, err)
+					}
+					b.W = n
+				case "k":
+					f, err := strconv.ParseFloat(value, 64)
+					if err != nil {
+						return nil, fmt.Errorf("invalid sauvola k %q: %w", value, err)
+					}
+					b.K = f
+				default:
+					return nil, fmt.Errorf("unknown sauvola parameter: %q", key)
+				}
+			}
+			steps = append(steps, b)
+		default:
+			return nil, fmt.Errorf("unknown preprocessing step: %q", name)
+		}
+	}
+
+	return steps, nil
+}
+
+func (c Config) backend() string {
+	if len(c.Backend) == 0 {
+		return "native"
+	}
+	return c.Backend
+}
+
+func (c Config) fileExtensions() []string {
+	if len(c.FileExtensions) == 0 {
+		return []string{".jpg", ".jpeg"}
+	}
+	return c.FileExtensions
+}
+
+func (c Config) jobs() int {
+	if c.Jobs <= 0 {
+		return runtime.NumCPU()
+	}
+	return c.Jobs
+}
+
+func (c Config) pageOrder() string {
+	if len(c.PageOrder) == 0 {
+		return "regex"
+	}
+	return c.PageOrder
+}
+
+func (c Config) pagePattern() string {
+	if len(c.PagePattern) == 0 {
+		return defaultPagePattern
+	}
+	return c.PagePattern
+}
+
+func (c Config) ocrLang() string {
+	if len(c.OCRLang) == 0 {
+		return "eng"
+	}
+	return c.OCRLang
+}
+
+// walkDirectorySearchPath walks fsys and sends matching file paths to the ingest channel.
+// the ingest channel is closed when the walk is complete
+func walkDirectorySearchPath(fsys fs.FS, extensions []string, ingest chan string) {
+	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// skip directories
+		if d.IsDir() {
+			return nil
+		}
+
+		// skip files that don't match the specified extensions
+		if !slices.Contains(extensions, filepath.Ext(path)) {
+			fmt.Println("skipping:", path)
+			return nil
+		}
+
+		ingest <- path
+		return nil
+	}); err != nil {
+		panic(err)
+	}
+	close(ingest)
+}
+
+// page represents a single matched file in the search directory, placed into its
+// final page position by a PageOrderer
+type page struct {
+	filepath string
+	tmpPath  string
+	hocrPath string // path to this page's recognized hOCR document, set by ocr()
+	cached   bool   // whether tmpPath is a cache-owned path pinned against eviction, set by generate()
+}
+
+// createTempFile creates a temporary file in the work directory with the same extension as the original file, and
+// assigns the path to the tmpPath field of the page struct for later use
+func (p *page) createTempFile(workDir string) error {
+	ext := filepath.Ext(p.filepath)
+
+	// ensure work directory exists
+	_ = os.Mkdir(workDir, os.ModePerm)
+
+	f, err := os.CreateTemp(workDir, "watermarker-*"+ext)
+	if err != nil {
+		return err
+	}
+	_ = f.Close() // no need to keep handle, we just want the file pattern generated and ready
+
+	p.tmpPath = f.Name()
+
+	return nil
+}
+
+// convert converts the original file to a new file with the specified dimensions and density
+func (p *page) convert(fsys fs.FS, r Renderer, cfg Config) error {
+	src, err := fsys.Open(p.filepath)
+	if err != nil {
+		return err
+	}
+
+	return r.Convert(src, p.tmpPath, cfg.OutputWidth, cfg.OutputHeight)
+}
+
+// watermark applies a watermark to the temporary file using the specified watermark image.
+// WatermarkPath is an OS filesystem path, not one resolved against the input fs.FS: the
+// watermark asset normally lives alongside, not inside, the scanned-pages directory.
+func (p *page) watermark(r Renderer, cfg Config) error {
+	wm, err := os.Open(cfg.WatermarkPath)
+	if err != nil {
+		return err
+	}
+
+	return r.Watermark(p.tmpPath, wm, cfg.WatermarkScale)
+}
+
+// ocr runs OCR against the page's processed temporary file and stores the resulting
+// hOCR document, used later to build a searchable text layer over this page. A page
+// that fails OCR is simply skipped for the text layer rather than failing the run.
+func (p *page) ocr(engine ocr.Engine, lang string) {
+	hocr, err := engine.Recognize(p.tmpPath, lang)
+	if err != nil {
+		fmt.Printf("warning: OCR failed for %s: %v\n", p.filepath, err)
+		return
+	}
+
+	// written to the OS temp directory rather than beside tmpPath, which may be
+	// inside the cache directory where another page's eviction pass could remove it
+	f, err := os.CreateTemp("", "watermarker-hocr-*.hocr")
+	if err != nil {
+		fmt.Printf("warning: OCR failed for %s: %v\n", p.filepath, err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(hocr); err != nil {
+		fmt.Printf("warning: OCR failed for %s: %v\n", p.filepath, err)
+		return
+	}
+
+	p.hocrPath = f.Name()
+}
+
+// cacheKey computes a content hash identifying the processed artifact this page
+// would produce, covering the source file's content, the output dimensions,
+// the watermark path and scale, the preprocessing steps applied, and the
+// rendering backend. Only the watermark's path is hashed, not its content, so
+// replacing the watermark image at the same path does not change the key.
+func (p *page) cacheKey(fsys fs.FS, cfg Config, preprocSpec string) (string, error) {
+	h := sha256.New()
+
+	f, err := fsys.Open(p.filepath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(h, "|%dx%d|%s|%d|%s|%s", cfg.OutputWidth, cfg.OutputHeight, cfg.WatermarkPath, cfg.WatermarkScale, cfg.backend(), preprocSpec)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// preprocess runs each configured preprocessing step, in order, against the page's
+// processed temporary file, overwriting it with the result. It runs after convert and
+// before watermark, so cleanup operates on the already-resized image.
+func (p *page) preprocess(steps []preproc.Preprocessor) error {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	img, err := imaging.Open(p.tmpPath)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		if img, err = step.Process(img); err != nil {
+			return err
+		}
+	}
+
+	return imaging.Save(img, p.tmpPath)
+}
+
+// generate generates a processed temporary file for the page, converting it to the specified dimensions and density,
+// running any configured preprocessing steps, applying a watermark if enabled, and OCR-ing it if engine is non-nil.
+// When c is non-nil, generate first checks the cache for an artifact matching this page's cacheKey and, if found,
+// reuses it in place of doing any work beyond OCR; otherwise it generates the artifact as usual and stores it in the
+// cache for next time. OCR always runs last, against the final (post-watermark) artifact, so a page's recognized
+// text doesn't depend on whether this run hit the cache.
+func (p *page) generate(fsys fs.FS, r Renderer, cfg Config, c *cache.Cache, steps []preproc.Preprocessor, preprocSpec string, engine ocr.Engine) error {
+	ext := filepath.Ext(p.filepath)
+
+	var key string
+	if c != nil {
+		var err error
+		if key, err = p.cacheKey(fsys, cfg, preprocSpec); err != nil {
+			return err
+		}
+		if path, ok := c.Lookup(key, ext); ok {
+			p.tmpPath = path
+			p.cached = true
+			if engine != nil {
+				p.ocr(engine, cfg.ocrLang())
+			}
+			return nil
+		}
+	}
+
+	if err := p.createTempFile(cfg.WorkDir); err != nil {
+		return err
+	} else if err := p.convert(fsys, r, cfg); err != nil {
+		return err
+	} else if err := p.preprocess(steps); err != nil {
+		return err
+	}
+
+	if len(cfg.WatermarkPath) > 0 {
+		if err := p.watermark(r, cfg); err != nil {
+			return err
+		}
+	}
+
+	if engine != nil {
+		p.ocr(engine, cfg.ocrLang())
+	}
+
+	if c != nil {
+		f, err := os.Open(p.tmpPath)
+		if err != nil {
+			return err
+		}
+		cached, err := c.Store(key, ext, f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+
+		_ = os.Remove(p.tmpPath)
+		p.tmpPath = cached
+		p.cached = true
+	}
+
+	return nil
+}
+
+// ingestPages walks fsys, collects all matched files, and places them into a slice of
+// page metadata structs in final page order using orderer
+func ingestPages(fsys fs.FS, extensions []string, orderer PageOrderer) ([]page, error) {
+	ingest := make(chan string)
+
+	go walkDirectorySearchPath(fsys, extensions, ingest)
+
+	var files []string
+	for path := range ingest {
+		files = append(files, path)
+
+		fmt.Printf("found page: %s\n", path)
+	}
+
+	ordered, err := orderer.Order(files)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]page, len(ordered))
+	for i, f := range ordered {
+		pages[i] = page{filepath: f}
+	}
+
+	return pages, nil
+}
+
+// genProcessedPages generates processed temporary files for each page in the slice of page metadata structs, updating
+// the tmpPath field with the path to the generated file. Processing runs across a bounded pool
+// of workers (see Config.Jobs) instead of one goroutine per page, and cancels remaining work
+// on the first error via ctx. When engine is non-nil, each page is also OCR'd; a page that fails
+// OCR only loses its text layer, it does not fail the run.
+func genProcessedPages(ctx context.Context, fsys fs.FS, pages []page, r Renderer, cfg Config, c *cache.Cache, steps []preproc.Preprocessor, preprocSpec string, engine ocr.Engine) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(cfg.jobs())
+
+	for i := range pages {
+		p := &pages[i]
+
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := p.generate(fsys, r, cfg, c, steps, preprocSpec, engine); err != nil {
+				return fmt.Errorf("error processing page %s: %w", p.filepath, err)
+			}
+
+			fmt.Printf("processed page: %s\n", p.filepath)
+			return nil
+		})
+	}
+
+	return g.Wait()
+}